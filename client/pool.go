@@ -0,0 +1,173 @@
+// Package client 实现跨多台 ESXi 主机复用的 govmomi 会话池
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vmware/govmomi"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// 后台保活协程的默认检测间隔
+const defaultKeepAliveInterval = 30 * time.Second
+
+// HostConfig 描述一台 ESXi 主机的连接信息
+type HostConfig struct {
+	Host     string // 主机地址, 例如 10.10.174.151 或 10.10.174.151:443
+	User     string
+	Password string
+	Insecure bool // 是否跳过 TLS 证书校验
+}
+
+// session 持有一个已登录的 govmomi 客户端及其对应的连接信息, 用于掉线后重连
+type session struct {
+	client *govmomi.Client
+	config HostConfig
+}
+
+// Pool 按 (host, user) 缓存 govmomi 客户端会话, 并在后台保持会话活跃
+type Pool struct {
+	mu        sync.RWMutex
+	sessions  map[string]*session
+	interval  time.Duration
+	stop      chan struct{}
+	keepAlive sync.Once
+}
+
+// NewPool 创建一个尚未建立任何连接的会话池
+func NewPool() *Pool {
+	return &Pool{
+		sessions: make(map[string]*session),
+		interval: defaultKeepAliveInterval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// poolKey 计算 (host, user) 在会话池中的键
+func poolKey(host string, user string) string {
+	return host + "|" + user
+}
+
+// Start 依次登录所有给定主机, 并启动后台保活协程 (仅在首次调用时启动); 任意一台登录失败
+// 都会中止并返回错误, 调用方可按需对不同主机分批调用以隔离彼此的登录失败
+func (p *Pool) Start(ctx context.Context, hosts []HostConfig) error {
+	for _, cfg := range hosts {
+		if _, err := p.connect(ctx, cfg); err != nil {
+			return fmt.Errorf("%w: 主机 %s: %v", errs.ErrConnect, cfg.Host, err)
+		}
+	}
+
+	p.keepAlive.Do(func() { go p.runKeepAlive(ctx) })
+
+	return nil
+}
+
+// StartFleet 登录 hosts[0] 指定的主主机 (失败即中止并返回错误), 随后尽力登录其余主机;
+// 某台额外主机登录失败只通过 onExtraFailure 回调通知调用方, 不影响主主机的可用性
+func (p *Pool) StartFleet(ctx context.Context, hosts []HostConfig, onExtraFailure func(HostConfig, error)) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	if err := p.Start(ctx, hosts[:1]); err != nil {
+		return err
+	}
+
+	for _, cfg := range hosts[1:] {
+		if err := p.Start(ctx, []HostConfig{cfg}); err != nil && onExtraFailure != nil {
+			onExtraFailure(cfg, err)
+		}
+	}
+
+	return nil
+}
+
+// connect 登录单台主机, 并将结果存入会话池 (已有会话会被覆盖)
+func (p *Pool) connect(ctx context.Context, cfg HostConfig) (*govmomi.Client, error) {
+	esxiURL, err := url.Parse(fmt.Sprintf("https://%s/sdk", cfg.Host))
+	if err != nil {
+		return nil, fmt.Errorf("%w: URL 解析失败: %v", errs.ErrInvalid, err)
+	}
+	esxiURL.User = url.UserPassword(cfg.User, cfg.Password)
+
+	govmomiClient, err := govmomi.NewClient(ctx, esxiURL, cfg.Insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.sessions[poolKey(cfg.Host, cfg.User)] = &session{client: govmomiClient, config: cfg}
+	p.mu.Unlock()
+
+	return govmomiClient, nil
+}
+
+// Get 返回 (host, user) 对应的已登录客户端, 未登录时返回 errs.ErrNotFound
+func (p *Pool) Get(host string, user string) (*govmomi.Client, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	s, ok := p.sessions[poolKey(host, user)]
+	if !ok {
+		return nil, fmt.Errorf("%w: 主机会话 %s@%s", errs.ErrNotFound, user, host)
+	}
+
+	return s.client, nil
+}
+
+// runKeepAlive 周期性检测每个会话是否仍然有效, 掉线时自动重新登录
+func (p *Pool) runKeepAlive(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.refresh(ctx)
+		}
+	}
+}
+
+// refresh 对当前所有会话执行一次存活检测与重连
+func (p *Pool) refresh(ctx context.Context) {
+	p.mu.RLock()
+	sessions := make([]*session, 0, len(p.sessions))
+	for _, s := range p.sessions {
+		sessions = append(sessions, s)
+	}
+	p.mu.RUnlock()
+
+	for _, s := range sessions {
+		active, err := s.client.SessionManager.SessionIsActive(ctx)
+		if err == nil && active {
+			continue
+		}
+
+		if _, err := p.connect(ctx, s.config); err != nil {
+			log.Printf("重新连接主机 %s 失败: %v", s.config.Host, err)
+		}
+	}
+}
+
+// Close 停止后台保活协程, 并登出池中所有会话
+func (p *Pool) Close(ctx context.Context) {
+	close(p.stop)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for key, s := range p.sessions {
+		_ = s.client.Logout(ctx)
+		delete(p.sessions, key)
+	}
+}
@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/limou3434/work-esxi-controller/client"
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// newTestModel 启动一个内置 vcsim 模拟的单机 ESXi 环境
+func newTestModel(t *testing.T) (*simulator.Model, *simulator.Server) {
+	t.Helper()
+
+	model := simulator.ESX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("创建模拟环境失败: %v", err)
+	}
+	model.Service.TLS = new(tls.Config)
+
+	return model, model.Service.NewServer()
+}
+
+func TestPoolStartAndGet(t *testing.T) {
+	model, server := newTestModel(t)
+	defer model.Remove()
+	defer server.Close()
+
+	ctx := context.Background()
+	pool := client.NewPool()
+
+	hosts := []client.HostConfig{
+		{Host: server.URL.Host, User: "user", Password: "pass", Insecure: true},
+	}
+	if err := pool.Start(ctx, hosts); err != nil {
+		t.Fatalf("启动会话池失败: %v", err)
+	}
+	defer pool.Close(ctx)
+
+	govmomiClient, err := pool.Get(hosts[0].Host, hosts[0].User)
+	if err != nil {
+		t.Fatalf("获取会话失败: %v", err)
+	}
+	if govmomiClient == nil {
+		t.Fatal("期望获取到非空客户端")
+	}
+}
+
+func TestPoolGetUnknownSession(t *testing.T) {
+	pool := client.NewPool()
+
+	_, err := pool.Get("未注册的主机", "user")
+	if !errors.Is(err, errs.ErrNotFound) {
+		t.Fatalf("期望返回 errs.ErrNotFound, 实际: %v", err)
+	}
+}
+
+func TestPoolStartFleetExtraHostFailureDoesNotBlockPrimary(t *testing.T) {
+	model, server := newTestModel(t)
+	defer model.Remove()
+	defer server.Close()
+
+	ctx := context.Background()
+	pool := client.NewPool()
+
+	primary := client.HostConfig{Host: server.URL.Host, User: "user", Password: "pass", Insecure: true}
+	unreachable := client.HostConfig{Host: "未注册的主机", User: "user", Password: "pass", Insecure: true}
+
+	var failed client.HostConfig
+	var failErr error
+	onExtraFailure := func(h client.HostConfig, err error) {
+		failed = h
+		failErr = err
+	}
+
+	if err := pool.StartFleet(ctx, []client.HostConfig{primary, unreachable}, onExtraFailure); err != nil {
+		t.Fatalf("主主机登录不应失败: %v", err)
+	}
+	defer pool.Close(ctx)
+
+	if failed.Host != unreachable.Host {
+		t.Fatalf("期望额外主机失败回调携带 %s, 实际: %s", unreachable.Host, failed.Host)
+	}
+	if !errors.Is(failErr, errs.ErrConnect) {
+		t.Fatalf("期望返回 errs.ErrConnect, 实际: %v", failErr)
+	}
+
+	if _, err := pool.Get(primary.Host, primary.User); err != nil {
+		t.Fatalf("主主机会话应可正常获取: %v", err)
+	}
+}
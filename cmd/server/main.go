@@ -0,0 +1,372 @@
+// cmd/server 将主机/虚拟机控制器暴露为 HTTP 服务, 供其他系统调用
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/vmware/govmomi/find"
+
+	"github.com/limou3434/work-esxi-controller/client"
+	"github.com/limou3434/work-esxi-controller/config"
+	"github.com/limou3434/work-esxi-controller/errs"
+	"github.com/limou3434/work-esxi-controller/events"
+	"github.com/limou3434/work-esxi-controller/host"
+	"github.com/limou3434/work-esxi-controller/render"
+	"github.com/limou3434/work-esxi-controller/vm"
+)
+
+// fleetControllers 缓存某台纳管主机对应的主机/虚拟机控制器
+type fleetControllers struct {
+	host *host.Controller
+	vm   *vm.Controller
+}
+
+// server 持有会话池与配置管理器, 按请求携带的主机地址懒加载对应的控制器;
+// events 固定绑定主主机, 事件订阅目前不支持按主机选择
+type server struct {
+	pool *client.Pool
+	cfg  *config.Manager
+
+	events *events.Controller
+
+	mu          sync.Mutex
+	controllers map[string]*fleetControllers
+}
+
+func main() {
+	cfg := config.NewManager()
+	if err := cfg.Load(); err != nil {
+		log.Fatal(err)
+	}
+	c := cfg.Current()
+
+	if c.Password == "" {
+		log.Fatal(fmt.Errorf("%w: 未配置 ESXi 密码, 可通过 ESXI_PASSWORD 环境变量或 config.yaml 设置", errs.ErrAuth))
+	}
+
+	ctx := context.Background()
+
+	pool := client.NewPool()
+	onExtraFailure := func(h client.HostConfig, err error) {
+		log.Printf("纳管主机 %s 失败, 已跳过: %v", h.Host, err)
+	}
+	if err := pool.StartFleet(ctx, c.Fleet(), onExtraFailure); err != nil {
+		log.Fatal(err)
+	}
+	defer pool.Close(ctx)
+
+	// config.yaml 变更后重新加载生效的配置; hosts 列表中新增的主机无需重启服务,
+	// 会在首次被 ?host= 选中时由 server.controllersFor 懒加载登录
+	cfg.Watch(func(config.Config) {
+		log.Printf("检测到 config.yaml 变更, 已重新加载配置")
+	})
+
+	srv, err := newServer(ctx, pool, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	addr := ":8080"
+	log.Printf("esxi 控制器服务监听于 %s", addr)
+	if err := http.ListenAndServe(addr, srv.routes()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// newServer 从会话池中取出主 ESXi 主机的已登录客户端, 并构建服务所需的控制器
+func newServer(ctx context.Context, pool *client.Pool, cfg *config.Manager) (*server, error) {
+	primary, _ := cfg.Current().HostByAddress("")
+
+	govmomiClient, err := pool.Get(primary.Host, primary.User)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceFinder := find.NewFinder(govmomiClient.Client, true)
+	datacenterObj, err := resourceFinder.Datacenter(ctx, primary.Datacenter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 数据中心 %s: %v", errs.ErrNotFound, primary.Datacenter, err)
+	}
+
+	return &server{
+		pool:        pool,
+		cfg:         cfg,
+		events:      events.NewController(govmomiClient, datacenterObj),
+		controllers: make(map[string]*fleetControllers),
+	}, nil
+}
+
+// controllersFor 返回 addr 对应的主机/虚拟机控制器 (addr 为空则为主主机), 首次访问
+// 时登录并缓存; addr 在纳管列表中但尚未登录 (例如 config.yaml 热加载后新增的主机)
+// 则即时补登
+func (s *server) controllersFor(ctx context.Context, addr string) (*host.Controller, *vm.Controller, error) {
+	hc, ok := s.cfg.Current().HostByAddress(addr)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: 主机 %s 未纳管", errs.ErrNotFound, addr)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fc, ok := s.controllers[hc.Host]; ok {
+		return fc.host, fc.vm, nil
+	}
+
+	govmomiClient, err := s.pool.Get(hc.Host, hc.User)
+	if err != nil {
+		loginErr := s.pool.Start(ctx, []client.HostConfig{
+			{Host: hc.Host, User: hc.User, Password: hc.Password, Insecure: hc.Insecure},
+		})
+		if loginErr != nil {
+			return nil, nil, loginErr
+		}
+
+		govmomiClient, err = s.pool.Get(hc.Host, hc.User)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	resourceFinder := find.NewFinder(govmomiClient.Client, true)
+	datacenterObj, err := resourceFinder.Datacenter(ctx, hc.Datacenter)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: 数据中心 %s: %v", errs.ErrNotFound, hc.Datacenter, err)
+	}
+
+	fc := &fleetControllers{
+		host: host.NewController(govmomiClient, datacenterObj),
+		vm:   vm.NewController(govmomiClient, datacenterObj),
+	}
+	s.controllers[hc.Host] = fc
+
+	return fc.host, fc.vm, nil
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /host", s.handleGetHost)
+	mux.HandleFunc("GET /datastores", s.handleGetDatastores)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("GET /events", s.handleEvents)
+	mux.HandleFunc("POST /vm", s.handleCreateVM)
+	mux.HandleFunc("POST /vm/{name}/power", s.handlePowerVM)
+	mux.HandleFunc("DELETE /vm/{name}", s.handleDeleteVM)
+
+	return mux
+}
+
+// hostParam 取出请求中 ?host= 指定的目标主机地址, 为空表示主 ESXi 主机
+func hostParam(r *http.Request) string {
+	return r.URL.Query().Get("host")
+}
+
+func (s *server) handleGetHost(w http.ResponseWriter, r *http.Request) {
+	hostController, _, err := s.controllersFor(r.Context(), hostParam(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	info, err := hostController.GetSystemInfo(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, info)
+}
+
+// handleMetrics 以 Prometheus 文本暴露格式输出主机与数据存储指标, 供 Prometheus/Grafana 抓取
+func (s *server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	hostController, _, err := s.controllersFor(r.Context(), hostParam(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	info, err := hostController.GetSystemInfo(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := (render.Prometheus{}).Render(w, info); err != nil {
+		writeError(w, err)
+		return
+	}
+}
+
+// handleEvents 以 Server-Sent Events 的形式持续推送主机/虚拟机/数据存储的属性变化
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, fmt.Errorf("%w: 当前响应不支持流式传输", errs.ErrInvalid))
+		return
+	}
+
+	stream, err := s.events.Subscribe(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for event := range stream {
+		data, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+
+		fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Kind, data)
+		flusher.Flush()
+	}
+}
+
+func (s *server) handleGetDatastores(w http.ResponseWriter, r *http.Request) {
+	hostController, _, err := s.controllersFor(r.Context(), hostParam(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	datastores, err := hostController.GetDatastores(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, datastores)
+}
+
+// createVMRequest 描述 POST /vm 的请求体, 根据 OVF 描述文件创建虚拟机;
+// Host 为空时在主 ESXi 主机上创建, 否则需为纳管列表中的主机地址
+type createVMRequest struct {
+	Host      string `json:"host"`
+	Name      string `json:"name"`
+	OVFPath   string `json:"ovf_path"`
+	Folder    string `json:"folder"`
+	Datastore string `json:"datastore"`
+	Network   string `json:"network"`
+}
+
+func (s *server) handleCreateVM(w http.ResponseWriter, r *http.Request) {
+	var req createVMRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, fmt.Errorf("%w: 请求体解析失败: %v", errs.ErrInvalid, err))
+		return
+	}
+
+	_, vmController, err := s.controllersFor(r.Context(), req.Host)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	created, err := vmController.CreateVMFromOVF(r.Context(), req.OVFPath, req.Name, req.Folder, req.Datastore, req.Network)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{"name": created.Name()})
+}
+
+// powerVMRequest 描述 POST /vm/{name}/power 的请求体
+type powerVMRequest struct {
+	Action string `json:"action"` // on | off | reset | suspend | reboot_guest | shutdown_guest
+}
+
+func (s *server) handlePowerVM(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	var req powerVMRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, fmt.Errorf("%w: 请求体解析失败: %v", errs.ErrInvalid, err))
+		return
+	}
+
+	ctx := r.Context()
+
+	_, vmController, err := s.controllersFor(ctx, hostParam(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	switch req.Action {
+	case "on":
+		err = vmController.PowerOn(ctx, name)
+	case "off":
+		err = vmController.PowerOff(ctx, name)
+	case "reset":
+		err = vmController.Reset(ctx, name)
+	case "suspend":
+		err = vmController.Suspend(ctx, name)
+	case "reboot_guest":
+		err = vmController.RebootGuest(ctx, name)
+	case "shutdown_guest":
+		err = vmController.ShutdownGuest(ctx, name)
+	default:
+		err = fmt.Errorf("%w: 未知的电源操作 %q", errs.ErrInvalid, req.Action)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) handleDeleteVM(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	_, vmController, err := s.controllersFor(r.Context(), hostParam(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if err := vmController.DeleteVM(r.Context(), name); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError 根据错误类别将其映射为对应的 HTTP 状态码
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, errs.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errs.ErrInvalid):
+		status = http.StatusBadRequest
+	case errors.Is(err, errs.ErrAuth):
+		status = http.StatusUnauthorized
+	case errors.Is(err, errs.ErrConnect):
+		status = http.StatusBadGateway
+	}
+
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
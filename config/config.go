@@ -0,0 +1,184 @@
+// Package config 实现分层配置加载: 默认值 -> config.yaml -> 环境变量 -> 命令行参数
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/limou3434/work-esxi-controller/client"
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// Host 描述一台 ESXi 主机的连接信息
+type Host struct {
+	Host       string `mapstructure:"host"`
+	User       string `mapstructure:"user"`
+	Password   string `mapstructure:"password"`
+	Insecure   bool   `mapstructure:"insecure"`
+	Datacenter string `mapstructure:"datacenter"`
+}
+
+// Config 是加载完成后的配置快照
+type Config struct {
+	Host       string `mapstructure:"host"`       // 主 ESXi 主机地址
+	User       string `mapstructure:"user"`       // 主 ESXi 用户名
+	Password   string `mapstructure:"password"`   // 主 ESXi 密码
+	Insecure   bool   `mapstructure:"insecure"`   // 是否跳过 TLS 证书校验
+	Datacenter string `mapstructure:"datacenter"` // 默认数据中心名称
+	LogLevel   string `mapstructure:"log_level"`  // 日志级别
+	Hosts      []Host `mapstructure:"hosts"`      // 额外纳管的 ESXi 主机, 用于 client.Pool
+}
+
+// Manager 基于 viper 实现分层配置加载与热更新
+type Manager struct {
+	v   *viper.Viper
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// NewManager 创建一个带有默认值的配置管理器
+func NewManager() *Manager {
+	v := viper.New()
+
+	v.SetDefault("host", "10.10.174.151")
+	v.SetDefault("user", "root")
+	v.SetDefault("password", "") // 必须通过 config.yaml / ESXI_PASSWORD / --password 设置, 不提供明文默认值
+	v.SetDefault("insecure", true)
+	v.SetDefault("datacenter", "ha-datacenter") // 裸 ESXi 默认数据中心名称
+	v.SetDefault("log_level", "info")
+
+	v.SetConfigName("config")
+	v.SetConfigType("yaml")
+	v.AddConfigPath(".")
+
+	v.SetEnvPrefix("ESXI")
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	v.AutomaticEnv()
+
+	return &Manager{v: v}
+}
+
+// BindFlags 将命令行参数接入配置层, 优先级高于环境变量与配置文件
+func (m *Manager) BindFlags(flags *pflag.FlagSet) error {
+	if err := m.v.BindPFlags(flags); err != nil {
+		return fmt.Errorf("%w: 绑定命令行参数失败: %v", errs.ErrInvalid, err)
+	}
+
+	return nil
+}
+
+// Load 按 默认值 -> config.yaml -> 环境变量 -> 命令行参数 的顺序解析出最终配置
+func (m *Manager) Load() error {
+	if err := m.v.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return fmt.Errorf("%w: 读取配置文件失败: %v", errs.ErrInvalid, err)
+		}
+	}
+
+	var cfg Config
+	if err := m.v.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("%w: 解析配置失败: %v", errs.ErrInvalid, err)
+	}
+
+	m.mu.Lock()
+	m.cfg = cfg
+	m.mu.Unlock()
+
+	return nil
+}
+
+// Current 返回当前生效的配置快照
+func (m *Manager) Current() Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.cfg
+}
+
+// Fleet 将主 ESXi 主机与 Hosts 中额外纳管的主机合并为会话池所需的连接信息列表,
+// 主主机排在首位
+func (c Config) Fleet() []client.HostConfig {
+	hosts := make([]client.HostConfig, 0, len(c.Hosts)+1)
+	hosts = append(hosts, client.HostConfig{
+		Host:     c.Host,
+		User:     c.User,
+		Password: c.Password,
+		Insecure: c.Insecure,
+	})
+
+	for _, h := range c.Hosts {
+		hosts = append(hosts, client.HostConfig{
+			Host:     h.Host,
+			User:     h.User,
+			Password: h.Password,
+			Insecure: h.Insecure,
+		})
+	}
+
+	return hosts
+}
+
+// HostByAddress 在主 ESXi 主机与 Hosts 中额外纳管的主机里查找 addr 对应的连接信息;
+// addr 为空或等于主主机地址时返回主主机本身
+func (c Config) HostByAddress(addr string) (Host, bool) {
+	primary := Host{
+		Host:       c.Host,
+		User:       c.User,
+		Password:   c.Password,
+		Insecure:   c.Insecure,
+		Datacenter: c.Datacenter,
+	}
+
+	if addr == "" || addr == c.Host {
+		return primary, true
+	}
+
+	for _, h := range c.Hosts {
+		if h.Host == addr {
+			return h, true
+		}
+	}
+
+	return Host{}, false
+}
+
+// Watch 在配置文件发生变化时重新解析配置, 并回调 onChange
+func (m *Manager) Watch(onChange func(Config)) {
+	m.v.OnConfigChange(func(_ fsnotify.Event) {
+		var cfg Config
+		if err := m.v.Unmarshal(&cfg); err != nil {
+			return
+		}
+
+		m.mu.Lock()
+		m.cfg = cfg
+		m.mu.Unlock()
+
+		onChange(cfg)
+	})
+	m.v.WatchConfig()
+}
+
+// Get 是一个独立于 Manager 接口的泛型取值函数: Go 的接口方法不支持自带类型参数,
+// 因此 Get[T] 只能实现为包级函数, 而不是 Manager 上可供接口约束的方法
+func Get[T any](m *Manager, key string) (T, error) {
+	var zero T
+
+	if !m.v.IsSet(key) {
+		return zero, fmt.Errorf("%w: 配置项 %s", errs.ErrNotFound, key)
+	}
+
+	var out T
+	if err := m.v.UnmarshalKey(key, &out); err != nil {
+		return zero, fmt.Errorf("%w: 配置项 %s: %v", errs.ErrInvalid, key, err)
+	}
+
+	return out, nil
+}
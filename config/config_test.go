@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/limou3434/work-esxi-controller/config"
+)
+
+func TestManagerLoadDefaults(t *testing.T) {
+	mgr := config.NewManager()
+
+	if err := mgr.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	c := mgr.Current()
+	if c.Host != "10.10.174.151" {
+		t.Fatalf("期望默认主机地址 10.10.174.151, 实际: %s", c.Host)
+	}
+	if c.Datacenter != "ha-datacenter" {
+		t.Fatalf("期望默认数据中心 ha-datacenter, 实际: %s", c.Datacenter)
+	}
+}
+
+func TestManagerLoadEnvOverride(t *testing.T) {
+	t.Setenv("ESXI_HOST", "192.168.1.10")
+	t.Setenv("ESXI_PASSWORD", "secret")
+
+	mgr := config.NewManager()
+	if err := mgr.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	c := mgr.Current()
+	if c.Host != "192.168.1.10" {
+		t.Fatalf("期望环境变量覆盖主机地址为 192.168.1.10, 实际: %s", c.Host)
+	}
+	if c.Password != "secret" {
+		t.Fatalf("期望环境变量覆盖密码为 secret, 实际: %s", c.Password)
+	}
+}
+
+func TestGetMissingKey(t *testing.T) {
+	mgr := config.NewManager()
+	if err := mgr.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+
+	if _, err := config.Get[string](mgr, "does_not_exist"); err == nil {
+		t.Fatal("期望未设置的配置项返回错误")
+	}
+}
+
+// TestManagerWatchReloadsOnConfigFileChange 验证 config.yaml 被修改后 Watch 会重新
+// 解析配置并通过回调通知调用方, 而不只是把 fsnotify 事件丢弃
+func TestManagerWatchReloadsOnConfigFileChange(t *testing.T) {
+	dir := t.TempDir()
+	configPath := dir + "/config.yaml"
+	if err := os.WriteFile(configPath, []byte("host: 10.0.0.1\n"), 0o644); err != nil {
+		t.Fatalf("写入配置文件失败: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("获取工作目录失败: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("切换工作目录失败: %v", err)
+	}
+	defer os.Chdir(wd)
+
+	mgr := config.NewManager()
+	if err := mgr.Load(); err != nil {
+		t.Fatalf("加载配置失败: %v", err)
+	}
+	if c := mgr.Current(); c.Host != "10.0.0.1" {
+		t.Fatalf("期望初始主机地址 10.0.0.1, 实际: %s", c.Host)
+	}
+
+	changed := make(chan config.Config, 1)
+	mgr.Watch(func(c config.Config) {
+		changed <- c
+	})
+
+	if err := os.WriteFile(configPath, []byte("host: 10.0.0.2\n"), 0o644); err != nil {
+		t.Fatalf("更新配置文件失败: %v", err)
+	}
+
+	select {
+	case c := <-changed:
+		if c.Host != "10.0.0.2" {
+			t.Fatalf("期望重新加载后主机地址为 10.0.0.2, 实际: %s", c.Host)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("期望配置文件变更后 Watch 的回调被触发")
+	}
+
+	if c := mgr.Current(); c.Host != "10.0.0.2" {
+		t.Fatalf("期望 Current() 返回重新加载后的主机地址 10.0.0.2, 实际: %s", c.Host)
+	}
+}
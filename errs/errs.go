@@ -0,0 +1,17 @@
+// Package errs 定义整个项目通用的哨兵错误, 供各层通过 errors.Is/errors.As 判断错误类别
+package errs
+
+import "errors"
+
+var (
+	// ErrAuth 表示身份认证相关的错误, 例如凭据缺失或密码错误
+	ErrAuth = errors.New("身份认证失败")
+	// ErrConnect 表示与 ESXi/vCenter 建立连接失败
+	ErrConnect = errors.New("连接失败")
+	// ErrNotFound 表示请求的资源不存在
+	ErrNotFound = errors.New("资源未找到")
+	// ErrTaskFailed 表示一个 govmomi 任务执行失败
+	ErrTaskFailed = errors.New("任务执行失败")
+	// ErrInvalid 表示传入的参数或文件不合法
+	ErrInvalid = errors.New("参数无效")
+)
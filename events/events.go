@@ -0,0 +1,226 @@
+// Package events 基于 govmomi PropertyCollector 将主机/虚拟机/数据存储的属性变化转换为事件流
+package events
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/vim25/types"
+)
+
+// Kind 标识事件的语义类别
+type Kind string
+
+const (
+	KindPowerState         Kind = "power_state"          // 主机或虚拟机电源状态变化
+	KindOverallStatus      Kind = "overall_status"       // 主机或虚拟机总体(告警)状态变化
+	KindDatastoreFreeSpace Kind = "datastore_free_space" // 数据存储可用空间下降
+	KindTaskCompleted      Kind = "task_completed"       // 任务(克隆/电源操作等)执行完成
+)
+
+// maxCompletedTasks 限制 completedTasks 去重集合的大小, 避免长时间保持的订阅
+// (例如 SSE 连接) 因持续产生新任务而无界增长
+const maxCompletedTasks = 1024
+
+// Event 描述一次被观察对象的属性变化
+type Event struct {
+	Kind    Kind
+	Object  string // 对象名称, 例如主机名/虚拟机名/数据存储名
+	Message string
+}
+
+// Controller 订阅数据中心内主机/虚拟机/数据存储的属性变化, 并翻译为 Event
+type Controller struct {
+	client     *govmomi.Client
+	finder     *find.Finder
+	datacenter *object.Datacenter
+}
+
+// NewController 创建一个绑定到指定数据中心的事件控制器实例
+func NewController(client *govmomi.Client, datacenter *object.Datacenter) *Controller {
+	finder := find.NewFinder(client.Client, true)
+	finder.SetDatacenter(datacenter)
+
+	return &Controller{
+		client:     client,
+		finder:     finder,
+		datacenter: datacenter,
+	}
+}
+
+// Subscribe 订阅数据中心下所有主机/虚拟机/数据存储的属性变化, 返回的 channel 会在 ctx 取消后关闭
+func (c *Controller) Subscribe(ctx context.Context) (<-chan Event, error) {
+	filter := new(property.WaitFilter)
+	names := make(map[types.ManagedObjectReference]string)
+
+	hostSystems, err := c.finder.HostSystemList(ctx, "*")
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	for _, h := range hostSystems {
+		names[h.Reference()] = h.Name()
+		filter.Add(h.Reference(), "HostSystem", []string{"summary.runtime.powerState", "summary.overallStatus"})
+	}
+
+	vms, err := c.finder.VirtualMachineList(ctx, "*")
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	for _, vm := range vms {
+		names[vm.Reference()] = vm.Name()
+		filter.Add(vm.Reference(), "VirtualMachine", []string{"summary.runtime.powerState", "summary.overallStatus"})
+	}
+
+	datastores, err := c.finder.DatastoreList(ctx, "*")
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+	for _, ds := range datastores {
+		names[ds.Reference()] = ds.Name()
+		filter.Add(ds.Reference(), "Datastore", []string{"summary.freeSpace"})
+	}
+
+	// TaskManager.recentTask 持续引用数据中心内近期提交的任务, 通过遍历该属性把
+	// Task 对象也纳入订阅范围, 从而收到任务执行完成的属性变化
+	taskManager := c.client.ServiceContent.TaskManager
+	if taskManager != nil {
+		filter.Add(taskManager.Reference(), "TaskManager", []string{"recentTask"}, &types.TraversalSpec{
+			Type: "TaskManager",
+			Path: "recentTask",
+		})
+		filter.Spec.PropSet = append(filter.Spec.PropSet, types.PropertySpec{
+			Type:    "Task",
+			PathSet: []string{"info"},
+		})
+	}
+
+	collector := property.DefaultCollector(c.client.Client)
+	out := make(chan Event)
+	freeSpace := make(map[types.ManagedObjectReference]int64)
+	completedTasks := make(map[types.ManagedObjectReference]bool)
+
+	go func() {
+		defer close(out)
+
+		_ = property.WaitForUpdates(ctx, collector, filter, func(updates []types.ObjectUpdate) bool {
+			for _, update := range updates {
+				name := names[update.Obj]
+
+				for _, change := range update.ChangeSet {
+					event, ok := translateChange(name, update.Obj, change, freeSpace, completedTasks)
+					if !ok {
+						continue
+					}
+
+					select {
+					case out <- event:
+					case <-ctx.Done():
+						return true
+					}
+				}
+			}
+
+			return ctx.Err() != nil
+		})
+	}()
+
+	return out, nil
+}
+
+// translateChange 将单条属性变化翻译为 Event, 数据存储可用空间仅在下降时产生事件,
+// 任务完成事件仅在任务进入 success/error 终态时产生一次
+func translateChange(name string, obj types.ManagedObjectReference, change types.PropertyChange, freeSpace map[types.ManagedObjectReference]int64, completedTasks map[types.ManagedObjectReference]bool) (Event, bool) {
+	switch change.Name {
+	case "summary.runtime.powerState":
+		return Event{
+			Kind:    KindPowerState,
+			Object:  name,
+			Message: fmt.Sprintf("%s 电源状态变更为 %v", name, change.Val),
+		}, true
+
+	case "summary.overallStatus":
+		return Event{
+			Kind:    KindOverallStatus,
+			Object:  name,
+			Message: fmt.Sprintf("%s 总体状态变更为 %v", name, change.Val),
+		}, true
+
+	case "summary.freeSpace":
+		free, ok := change.Val.(int64)
+		if !ok {
+			return Event{}, false
+		}
+
+		prev, seen := freeSpace[obj]
+		freeSpace[obj] = free
+
+		if seen && free < prev {
+			return Event{
+				Kind:    KindDatastoreFreeSpace,
+				Object:  name,
+				Message: fmt.Sprintf("%s 可用空间从 %d 字节降至 %d 字节", name, prev, free),
+			}, true
+		}
+
+		return Event{}, false
+
+	case "info":
+		info, ok := change.Val.(types.TaskInfo)
+		if !ok {
+			return Event{}, false
+		}
+
+		if info.State != types.TaskInfoStateSuccess && info.State != types.TaskInfoStateError {
+			return Event{}, false
+		}
+
+		if completedTasks[obj] {
+			return Event{}, false
+		}
+		if len(completedTasks) >= maxCompletedTasks {
+			// completedTasks 只用于在同一个 Task 的多次终态属性更新间去重, 长时间
+			// 保持的订阅 (例如 SSE 连接) 不应让它无界增长, 达到上限后随意淘汰一项
+			for k := range completedTasks {
+				delete(completedTasks, k)
+				break
+			}
+		}
+		completedTasks[obj] = true
+
+		target := info.DescriptionId
+		if info.EntityName != "" {
+			target = fmt.Sprintf("%s (%s)", info.DescriptionId, info.EntityName)
+		}
+
+		if info.State == types.TaskInfoStateError {
+			message := "未知错误"
+			if info.Error != nil {
+				message = info.Error.LocalizedMessage
+			}
+			return Event{
+				Kind:    KindTaskCompleted,
+				Object:  info.EntityName,
+				Message: fmt.Sprintf("任务 %s 执行失败: %s", target, message),
+			}, true
+		}
+
+		return Event{
+			Kind:    KindTaskCompleted,
+			Object:  info.EntityName,
+			Message: fmt.Sprintf("任务 %s 执行成功", target),
+		}, true
+
+	default:
+		return Event{}, false
+	}
+}
+
+// isNotFound 判断 finder 的查找结果是否为"未找到任何对象"
+func isNotFound(err error) bool {
+	_, ok := err.(*find.NotFoundError)
+	return ok
+}
@@ -0,0 +1,125 @@
+package events_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/limou3434/work-esxi-controller/events"
+)
+
+// newTestEnv 启动一个内置 vcsim 模拟的单机 ESXi 环境, 返回事件控制器与一个预置虚拟机
+func newTestEnv(t *testing.T) (context.Context, *events.Controller, *object.VirtualMachine, func()) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	model := simulator.ESX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("创建模拟环境失败: %v", err)
+	}
+
+	server := model.Service.NewServer()
+
+	client, err := govmomi.NewClient(ctx, server.URL, true)
+	if err != nil {
+		model.Remove()
+		server.Close()
+		t.Fatalf("连接模拟环境失败: %v", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.DefaultDatacenter(ctx)
+	if err != nil {
+		t.Fatalf("查找默认数据中心失败: %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil || len(vms) == 0 {
+		t.Fatalf("列出预置虚拟机失败: %v", err)
+	}
+
+	cleanup := func() {
+		_ = client.Logout(ctx)
+		model.Remove()
+		server.Close()
+	}
+
+	return ctx, events.NewController(client, datacenter), vms[0], cleanup
+}
+
+func TestSubscribePowerStateChange(t *testing.T) {
+	ctx, controller, vm, cleanup := newTestEnv(t)
+	defer cleanup()
+
+	subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stream, err := controller.Subscribe(subCtx)
+	if err != nil {
+		t.Fatalf("订阅事件失败: %v", err)
+	}
+
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatalf("关闭虚拟机失败: %v", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("等待关机任务失败: %v", err)
+	}
+
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				t.Fatal("事件流提前关闭, 未收到电源状态事件")
+			}
+			if event.Kind == events.KindPowerState {
+				return
+			}
+		case <-subCtx.Done():
+			t.Fatal("等待电源状态事件超时")
+		}
+	}
+}
+
+func TestSubscribeTaskCompletedEvent(t *testing.T) {
+	ctx, controller, vm, cleanup := newTestEnv(t)
+	defer cleanup()
+
+	subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stream, err := controller.Subscribe(subCtx)
+	if err != nil {
+		t.Fatalf("订阅事件失败: %v", err)
+	}
+
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		t.Fatalf("关闭虚拟机失败: %v", err)
+	}
+	if err := task.Wait(ctx); err != nil {
+		t.Fatalf("等待关机任务失败: %v", err)
+	}
+
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				t.Fatal("事件流提前关闭, 未收到任务完成事件")
+			}
+			if event.Kind == events.KindTaskCompleted {
+				return
+			}
+		case <-subCtx.Done():
+			t.Fatal("等待任务完成事件超时")
+		}
+	}
+}
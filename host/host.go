@@ -0,0 +1,98 @@
+// Package host 封装 ESXi 主机与数据存储信息的查询, 供表格/JSON/Prometheus 等展现层复用
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// DatastoreInfo 描述单个数据存储的容量信息, 单位为字节
+type DatastoreInfo struct {
+	Name          string
+	CapacityBytes int64
+	FreeBytes     int64
+}
+
+// Info 描述一台 ESXi 主机的状态快照
+type Info struct {
+	Name          string
+	OverallStatus types.ManagedEntityStatus
+	PowerState    types.HostSystemPowerState
+	CpuMhz        int32
+	MemoryBytes   int64
+	Datastores    []DatastoreInfo
+}
+
+// Controller 基于 govmomi 客户端实现 SystemController 接口
+type Controller struct {
+	client     *govmomi.Client
+	finder     *find.Finder
+	datacenter *object.Datacenter
+}
+
+// NewController 创建一个绑定到指定数据中心的主机控制器实例
+func NewController(client *govmomi.Client, datacenter *object.Datacenter) *Controller {
+	finder := find.NewFinder(client.Client, true)
+	finder.SetDatacenter(datacenter)
+
+	return &Controller{
+		client:     client,
+		finder:     finder,
+		datacenter: datacenter,
+	}
+}
+
+// GetSystemInfo 查询默认主机及其挂载数据存储的状态快照
+func (c *Controller) GetSystemInfo(ctx context.Context) (*Info, error) {
+	hostSystem, err := c.finder.DefaultHostSystem(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 默认主机: %v", errs.ErrNotFound, err)
+	}
+
+	hostSystemMO := mo.HostSystem{}
+	err = c.client.RetrieveOne(ctx, hostSystem.Reference(), []string{"name", "summary", "datastore"}, &hostSystemMO)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 读取主机属性: %v", errs.ErrConnect, err)
+	}
+
+	info := &Info{
+		Name:          hostSystemMO.Name,
+		OverallStatus: hostSystemMO.Summary.OverallStatus,
+		PowerState:    hostSystemMO.Summary.Runtime.PowerState,
+		CpuMhz:        hostSystemMO.Summary.Hardware.CpuMhz,
+		MemoryBytes:   hostSystemMO.Summary.Hardware.MemorySize,
+	}
+
+	for _, dsRef := range hostSystemMO.Datastore {
+		ds := mo.Datastore{}
+		if err := c.client.RetrieveOne(ctx, dsRef, []string{"summary"}, &ds); err != nil {
+			return nil, fmt.Errorf("%w: 数据存储 %s: %v", errs.ErrConnect, dsRef.Value, err)
+		}
+
+		info.Datastores = append(info.Datastores, DatastoreInfo{
+			Name:          ds.Summary.Name,
+			CapacityBytes: ds.Summary.Capacity,
+			FreeBytes:     ds.Summary.FreeSpace,
+		})
+	}
+
+	return info, nil
+}
+
+// GetDatastores 单独查询默认主机挂载的数据存储列表
+func (c *Controller) GetDatastores(ctx context.Context) ([]DatastoreInfo, error) {
+	info, err := c.GetSystemInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Datastores, nil
+}
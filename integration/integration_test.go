@@ -0,0 +1,109 @@
+// Package integration_test 在不依赖真实 vSphere 环境的前提下,
+// 使用内置 vcsim 模拟器串联主机查询/虚拟机生命周期/事件订阅三条主要代码路径
+package integration_test
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/limou3434/work-esxi-controller/events"
+	"github.com/limou3434/work-esxi-controller/host"
+	"github.com/limou3434/work-esxi-controller/vm"
+)
+
+// newTestEnv 启动一个内置 vcsim 模拟的单机 ESXi 环境, 并登录得到绑定同一数据中心的
+// 主机/虚拟机/事件三个控制器, 模拟一次真实的服务启动过程
+func newTestEnv(t *testing.T) (context.Context, *host.Controller, *vm.Controller, *events.Controller, []string, func()) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	model := simulator.ESX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("创建模拟环境失败: %v", err)
+	}
+	model.Service.TLS = new(tls.Config)
+
+	server := model.Service.NewServer()
+
+	client, err := govmomi.NewClient(ctx, server.URL, true)
+	if err != nil {
+		model.Remove()
+		server.Close()
+		t.Fatalf("连接模拟环境失败: %v", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.DefaultDatacenter(ctx)
+	if err != nil {
+		t.Fatalf("查找默认数据中心失败: %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil || len(vms) == 0 {
+		t.Fatalf("列出预置虚拟机失败: %v", err)
+	}
+	names := make([]string, len(vms))
+	for i, v := range vms {
+		names[i] = v.Name()
+	}
+
+	cleanup := func() {
+		_ = client.Logout(ctx)
+		model.Remove()
+		server.Close()
+	}
+
+	return ctx, host.NewController(client, datacenter), vm.NewController(client, datacenter), events.NewController(client, datacenter), names, cleanup
+}
+
+// TestIntegrationHostVMEventFlow 依次验证主机状态查询/虚拟机电源操作/事件订阅三条路径
+// 能否在同一个模拟环境中协同工作: 关闭一台虚拟机后, 事件订阅应当能观察到对应的电源状态变化
+func TestIntegrationHostVMEventFlow(t *testing.T) {
+	ctx, hostController, vmController, eventsController, names, cleanup := newTestEnv(t)
+	defer cleanup()
+
+	info, err := hostController.GetSystemInfo(ctx)
+	if err != nil {
+		t.Fatalf("查询主机状态失败: %v", err)
+	}
+	if info.Name == "" {
+		t.Fatal("期望返回非空主机名称")
+	}
+	if len(info.Datastores) == 0 {
+		t.Fatal("期望模拟环境预置至少一个数据存储")
+	}
+
+	subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	stream, err := eventsController.Subscribe(subCtx)
+	if err != nil {
+		t.Fatalf("订阅事件失败: %v", err)
+	}
+
+	if err := vmController.PowerOff(ctx, names[0]); err != nil {
+		t.Fatalf("关闭虚拟机 %s 失败: %v", names[0], err)
+	}
+
+	for {
+		select {
+		case event, ok := <-stream:
+			if !ok {
+				t.Fatal("事件流提前关闭, 未观察到虚拟机电源状态变化")
+			}
+			if event.Kind == events.KindPowerState && event.Object == names[0] {
+				return
+			}
+		case <-subCtx.Done():
+			t.Fatal("等待虚拟机电源状态事件超时")
+		}
+	}
+}
@@ -3,191 +3,187 @@ package main
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"os"
-	"strconv"
 
-	"github.com/joho/godotenv"
-	"github.com/olekukonko/tablewriter"
-	"github.com/vmware/govmomi"
+	"github.com/spf13/cobra"
 	"github.com/vmware/govmomi/find"
-	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/object"
+
+	"github.com/limou3434/work-esxi-controller/client"
+	"github.com/limou3434/work-esxi-controller/config"
+	"github.com/limou3434/work-esxi-controller/errs"
+	"github.com/limou3434/work-esxi-controller/host"
+	"github.com/limou3434/work-esxi-controller/render"
+	"github.com/limou3434/work-esxi-controller/vm"
 )
 
-func translateOverallStatus(status string) string {
-	switch status {
-	case "gray":
-		return "未知(可能是裸机状态)"
-	case "green":
-		return "正常"
-	case "yellow":
-		return "警告"
-	case "red":
-		return "异常"
-	default:
-		return status
+// newHostController 从会话池中取出 target 对应的已登录客户端, 并返回绑定到其数据中心的主机控制器
+func newHostController(ctx context.Context, pool *client.Pool, target config.Host) (*host.Controller, error) {
+	govmomiClient, err := pool.Get(target.Host, target.User)
+	if err != nil {
+		return nil, err
 	}
-}
 
-func translatePowerState(state string) string {
-	switch state {
-	case "poweredOn":
-		return "开机"
-	case "poweredOff":
-		return "关机"
-	case "standBy":
-		return "待机"
-	default:
-		return state
+	resourceFinder := find.NewFinder(govmomiClient.Client, true)
+	datacenterObj, err := resourceFinder.Datacenter(ctx, target.Datacenter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 数据中心 %s: %v", errs.ErrNotFound, target.Datacenter, err)
 	}
-}
 
-// 创建 ESXi URL 链接实例
-func CreateESXiURL(esxiHost string, esxiUser string, esxiPassword string) *url.URL {
-	esxiURLString := fmt.Sprintf("https://%s/sdk", esxiHost)
+	return host.NewController(govmomiClient, datacenterObj), nil
+}
 
-	esxiURL, esxiURLParseError := url.Parse(esxiURLString)
-	if esxiURLParseError != nil {
-		fmt.Println("URL 解析失败:", esxiURLParseError)
-		os.Exit(1) // TODO: 强制退出是有点问题的, 以后再把错误集中处理, 先等项目搭建完毕
+// rendererByName 按名称选取输出渲染器, 默认为表格
+func rendererByName(name string) (render.Renderer, error) {
+	switch name {
+	case "", "table":
+		return render.Table{}, nil
+	case "json":
+		return render.JSON{}, nil
+	case "yaml":
+		return render.YAML{}, nil
+	case "prometheus":
+		return render.Prometheus{}, nil
+	default:
+		return nil, fmt.Errorf("%w: 未知的输出格式 %q", errs.ErrInvalid, name)
 	}
-
-	esxiURL.User = url.UserPassword(esxiUser, esxiPassword)
-
-	return esxiURL
 }
 
-// 打印主机表格
-func PrintHostInfoTable(esxiHost string, esxiUser string, esxiPassword string) {
-	// 创建上下文实例
-	contextRoot := context.Background()
-
-	// 创建 ESXi URL 链接实例
-	esxiURL := CreateESXiURL(esxiHost, esxiUser, esxiPassword)
-
-	// 创建 Govmomi 客户端实例
-	govmomiClient, govmomiClientError := govmomi.NewClient(contextRoot, esxiURL, true)
-	if govmomiClientError != nil {
-		fmt.Println("连接失败:", govmomiClientError)
-		os.Exit(1)
+// PrintHostInfo 登录配置中纳管的整个 ESXi 主机群, 查询 targetHost 指定主机 (为空则为主
+// ESXi 主机) 的状态快照, 并以指定格式渲染到标准输出
+func PrintHostInfo(c config.Config, targetHost string, format string) error {
+	renderer, err := rendererByName(format)
+	if err != nil {
+		return err
 	}
-	defer govmomiClient.Logout(contextRoot)
 
-	// 创建 Finder 资源查找器实例
-	resourceFinder := find.NewFinder(govmomiClient.Client, true)
-	datacenter, datacenterError := resourceFinder.Datacenter(contextRoot, "ha-datacenter") // 裸 ESXi 默认数据中心名称
-	if datacenterError != nil {
-		fmt.Println("找不到默认数据中心:", datacenterError)
-		os.Exit(1)
+	target, ok := c.HostByAddress(targetHost)
+	if !ok {
+		return fmt.Errorf("%w: 主机 %s 未纳管", errs.ErrNotFound, targetHost)
 	}
-	resourceFinder.SetDatacenter(datacenter)
 
-	// 获取默认主机实例
-	hostSystem, hostSystemError := resourceFinder.DefaultHostSystem(contextRoot)
-	if hostSystemError != nil {
-		fmt.Println("主机查找失败:", hostSystemError)
-		os.Exit(1)
-	}
+	contextRoot := context.Background()
 
-	// 读取主机属性
-	hostSystemMO := mo.HostSystem{}
-	hostSystemPropertyError := govmomiClient.RetrieveOne(
-		contextRoot,
-		hostSystem.Reference(),
-		[]string{
-			"name",      // 主机名字
-			"summary",   // 主机摘要
-			"datastore", // 挂在存储
-		},
-		&hostSystemMO,
-	)
-	if hostSystemPropertyError != nil {
-		fmt.Println("读取主机属性失败:", hostSystemPropertyError)
-		os.Exit(1)
+	pool := client.NewPool()
+	onExtraFailure := func(h client.HostConfig, err error) {
+		fmt.Fprintf(os.Stderr, "警告: 纳管主机 %s 失败, 已跳过: %v\n", h.Host, err)
 	}
-
-	// 打印主机状态表格
-	data := [][]string{
-		{"主机名称", hostSystemMO.Name},
-		{"总体状态", translateOverallStatus(string(hostSystemMO.Summary.OverallStatus))},
-		{"电源状态", translatePowerState(string(hostSystemMO.Summary.Runtime.PowerState))},
-		{"中央处理", strconv.Itoa(int(hostSystemMO.Summary.Hardware.CpuMhz)) + " MHz"},
-		{"内存大小", strconv.FormatInt(hostSystemMO.Summary.Hardware.MemorySize/1024/1024/1024, 10) + " GB"},
-		{"存储数量", strconv.Itoa(len(hostSystemMO.Datastore)) + " 个磁盘"},
+	if err := pool.StartFleet(contextRoot, c.Fleet(), onExtraFailure); err != nil {
+		return err
 	}
+	defer pool.Close(contextRoot)
 
-	for _, dsRef := range hostSystemMO.Datastore {
-		ds := mo.Datastore{}
-		err := govmomiClient.RetrieveOne(contextRoot, dsRef, []string{"summary"}, &ds)
-		if err != nil {
-			fmt.Println("获取 datastore 信息失败:", err)
-			continue
-		}
-		capacityGB := ds.Summary.Capacity / 1024 / 1024 / 1024
-		freeGB := ds.Summary.FreeSpace / 1024 / 1024 / 1024
-		uncommittedGB := capacityGB - freeGB
-		diskInfo := fmt.Sprintf("总容量: %d GB, 可用空间: %d GB, 已用空间: %d GB", capacityGB, freeGB, uncommittedGB)
-		data = append(data, []string{ds.Summary.Name, diskInfo})
+	hostController, err := newHostController(contextRoot, pool, target)
+	if err != nil {
+		return err
 	}
 
-	table := tablewriter.NewWriter(os.Stdout)
-
-	table.Header([]string{"字段", "对应值"})
-	for _, v := range data {
-		table.Append(v)
+	info, err := hostController.GetSystemInfo(contextRoot)
+	if err != nil {
+		return err
 	}
-	table.Render()
+
+	return renderer.Render(os.Stdout, info)
 }
 
-// 获取环境变量中的密码
-func getEnvsPassword() string {
-	// 加载当前目录下的 .env 文件
-	err := godotenv.Load(".env")
-	if err != nil {
-		fmt.Println("加载 .env 文件失败:", err)
-		os.Exit(1)
+// newRootCmd 构建根命令, 将命令行参数接入配置层, 随后加载分层配置并打印主机表格
+func newRootCmd(cfg *config.Manager) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "work-esxi-controller",
+		Short: "查看 ESXi 主机与数据存储状态",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cfg.BindFlags(cmd.Flags()); err != nil {
+				return err
+			}
+			if err := cfg.Load(); err != nil {
+				return err
+			}
+
+			c := cfg.Current()
+			if c.Password == "" {
+				return fmt.Errorf("%w: 未配置 ESXi 密码, 可通过 ESXI_PASSWORD 环境变量或 --password 设置", errs.ErrAuth)
+			}
+
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			targetHost, err := cmd.Flags().GetString("target-host")
+			if err != nil {
+				return err
+			}
+
+			return PrintHostInfo(c, targetHost, output)
+		},
 	}
 
-	esxiPassword := os.Getenv("ESXI_PASSWORD") // ESXi 密码
-	if esxiPassword == "" {
-		fmt.Println("ESXI_PASSWORD 未设置")
-		os.Exit(1)
-	}
+	cmd.Flags().String("host", "", "ESXi 主机地址")
+	cmd.Flags().String("user", "", "ESXi 用户名")
+	cmd.Flags().String("password", "", "ESXi 密码")
+	cmd.Flags().String("datacenter", "", "默认数据中心名称")
+	cmd.Flags().Bool("insecure", true, "是否跳过 TLS 证书校验")
+	cmd.Flags().String("log-level", "", "日志级别")
+	cmd.Flags().String("output", "table", "输出格式: table | json | yaml | prometheus")
+	cmd.Flags().String("target-host", "", "查询 hosts 中纳管的指定主机, 为空则查询主 ESXi 主机")
 
-	return esxiPassword
+	return cmd
 }
 
 func main() {
-	// 设置 ESXi 主机基本信息
+	cfg := config.NewManager()
 
-	esxiHost := "10.10.174.151"       // ESXi 主机地址
-	esxiUser := "root"                // ESXi 用户名
-	esxiPassword := getEnvsPassword() // ESXi 密码
-	PrintHostInfoTable(esxiHost, esxiUser, esxiPassword)
+	if err := newRootCmd(cfg).Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 }
 
 // TODO: 构建一个完整的架构过程
 
 /**
- * 操作系统控制器接口
+ * 操作系统控制器接口, 由 host.Controller 实现
  */
 type SystemController interface {
 	// 获取系统信息
-	GetSystemInfo()
+	GetSystemInfo(ctx context.Context) (*host.Info, error)
 }
 
 /**
- * 虚拟机控制器接口
+ * 虚拟机控制器接口, 由 vm.Controller 实现
  */
 type VMController interface {
-	// 创建虚拟机
-	CreateVMSystem()
+	// 根据 OVF 描述文件创建虚拟机
+	CreateVMFromOVF(ctx context.Context, ovfPath string, name string, folder string, datastore string, network string) (*object.VirtualMachine, error)
+	// 克隆虚拟机
+	CloneVM(ctx context.Context, srcName string, dstName string, spec vm.CloneSpec) (*object.VirtualMachine, error)
+	// 删除虚拟机
+	DeleteVM(ctx context.Context, name string) error
+	// 开机
+	PowerOn(ctx context.Context, name string) error
+	// 关机
+	PowerOff(ctx context.Context, name string) error
+	// 硬重启
+	Reset(ctx context.Context, name string) error
+	// 挂起
+	Suspend(ctx context.Context, name string) error
+	// 通过 VMware Tools 重启客户机操作系统
+	RebootGuest(ctx context.Context, name string) error
+	// 通过 VMware Tools 关闭客户机操作系统
+	ShutdownGuest(ctx context.Context, name string) error
+	// 等待虚拟机上报 IP 地址
+	WaitForIP(ctx context.Context, name string) (string, error)
 }
 
 /**
- * 工具接口
+ * 配置接口, 由 config.Manager 实现
+ *
+ * 注意: Go 的接口方法不能携带自己的类型参数, 因此 config.Get[T] 提供为包级泛型函数,
+ * 未出现在这里的接口定义中
  */
-type Tools interface {
-	// 获取某个环境变量
-	GetEnv()
+type Config interface {
+	// 按 默认值 -> config.yaml -> 环境变量 -> 命令行参数 的顺序加载配置
+	Load() error
+	// 配置文件发生变化时重新加载并回调
+	Watch(onChange func(config.Config))
 }
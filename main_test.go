@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/limou3434/work-esxi-controller/client"
+	"github.com/limou3434/work-esxi-controller/config"
+)
+
+// newTestModel 启动一个内置 vcsim 模拟的单机 ESXi 环境, 用于验证 newHostController
+// 能否直接接受模拟器的 URL, 而无需任何真实的 vSphere 基础设施
+func newTestModel(t *testing.T) (*simulator.Model, *simulator.Server) {
+	t.Helper()
+
+	model := simulator.ESX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("创建模拟环境失败: %v", err)
+	}
+	model.Service.TLS = new(tls.Config)
+
+	return model, model.Service.NewServer()
+}
+
+func TestNewHostControllerAgainstSimulator(t *testing.T) {
+	model, server := newTestModel(t)
+	defer model.Remove()
+	defer server.Close()
+
+	ctx := context.Background()
+
+	pool := client.NewPool()
+	if err := pool.Start(ctx, []client.HostConfig{{Host: server.URL.Host, User: "user", Password: "pass", Insecure: true}}); err != nil {
+		t.Fatalf("登录模拟环境失败: %v", err)
+	}
+	defer pool.Close(ctx)
+
+	hostController, err := newHostController(ctx, pool, config.Host{Host: server.URL.Host, User: "user", Datacenter: "ha-datacenter"})
+	if err != nil {
+		t.Fatalf("构建主机控制器失败: %v", err)
+	}
+
+	info, err := hostController.GetSystemInfo(ctx)
+	if err != nil {
+		t.Fatalf("查询主机状态失败: %v", err)
+	}
+	if info.Name == "" {
+		t.Fatal("期望返回非空主机名称")
+	}
+}
+
+func TestPrintHostInfoUnknownFormat(t *testing.T) {
+	model, server := newTestModel(t)
+	defer model.Remove()
+	defer server.Close()
+
+	c := config.Config{
+		Host:       server.URL.Host,
+		User:       "user",
+		Password:   "pass",
+		Datacenter: "ha-datacenter",
+		Insecure:   true,
+	}
+
+	err := PrintHostInfo(c, "", "xml")
+	if err == nil {
+		t.Fatal("期望未知输出格式返回错误")
+	}
+}
+
+func TestPrintHostInfoUnknownTargetHost(t *testing.T) {
+	model, server := newTestModel(t)
+	defer model.Remove()
+	defer server.Close()
+
+	c := config.Config{
+		Host:       server.URL.Host,
+		User:       "user",
+		Password:   "pass",
+		Datacenter: "ha-datacenter",
+		Insecure:   true,
+	}
+
+	err := PrintHostInfo(c, "未纳管的主机", "table")
+	if err == nil {
+		t.Fatal("期望未纳管的目标主机返回错误")
+	}
+}
@@ -0,0 +1,25 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+	"github.com/limou3434/work-esxi-controller/host"
+)
+
+// JSON 以机器可读的 JSON 格式输出主机快照, 供自动化脚本消费
+type JSON struct{}
+
+// Render 实现 Renderer 接口
+func (JSON) Render(w io.Writer, info *host.Info) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(info); err != nil {
+		return fmt.Errorf("%w: JSON 编码失败: %v", errs.ErrInvalid, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/limou3434/work-esxi-controller/host"
+)
+
+// Prometheus 以 Prometheus 文本暴露格式输出主机快照, 供 /metrics 端点抓取
+type Prometheus struct{}
+
+// Render 实现 Renderer 接口
+func (Prometheus) Render(w io.Writer, info *host.Info) error {
+	fmt.Fprintf(w, "# HELP esxi_host_cpu_mhz 主机 CPU 主频\n")
+	fmt.Fprintf(w, "# TYPE esxi_host_cpu_mhz gauge\n")
+	fmt.Fprintf(w, "esxi_host_cpu_mhz %d\n", info.CpuMhz)
+
+	fmt.Fprintf(w, "# HELP esxi_host_memory_bytes 主机内存容量\n")
+	fmt.Fprintf(w, "# TYPE esxi_host_memory_bytes gauge\n")
+	fmt.Fprintf(w, "esxi_host_memory_bytes %d\n", info.MemoryBytes)
+
+	fmt.Fprintf(w, "# HELP esxi_host_power_state 主机电源状态, 1 表示开机, 0 表示非开机\n")
+	fmt.Fprintf(w, "# TYPE esxi_host_power_state gauge\n")
+	fmt.Fprintf(w, "esxi_host_power_state %d\n", powerStateValue(info.PowerState))
+
+	fmt.Fprintf(w, "# HELP esxi_datastore_capacity_bytes 数据存储总容量\n")
+	fmt.Fprintf(w, "# TYPE esxi_datastore_capacity_bytes gauge\n")
+	for _, ds := range info.Datastores {
+		fmt.Fprintf(w, "esxi_datastore_capacity_bytes{name=%q} %d\n", ds.Name, ds.CapacityBytes)
+	}
+
+	fmt.Fprintf(w, "# HELP esxi_datastore_free_bytes 数据存储剩余空间\n")
+	fmt.Fprintf(w, "# TYPE esxi_datastore_free_bytes gauge\n")
+	for _, ds := range info.Datastores {
+		fmt.Fprintf(w, "esxi_datastore_free_bytes{name=%q} %d\n", ds.Name, ds.FreeBytes)
+	}
+
+	return nil
+}
+
+// powerStateValue 将电源状态映射为 Prometheus gauge 使用的数值
+func powerStateValue(state types.HostSystemPowerState) int {
+	if state == types.HostSystemPowerStatePoweredOn {
+		return 1
+	}
+
+	return 0
+}
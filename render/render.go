@@ -0,0 +1,43 @@
+// Package render 将 host.Info 快照渲染为表格/JSON/YAML/Prometheus 等展现形式
+package render
+
+import (
+	"io"
+
+	"github.com/limou3434/work-esxi-controller/host"
+)
+
+// Renderer 将主机状态快照写入指定的输出流
+type Renderer interface {
+	Render(w io.Writer, info *host.Info) error
+}
+
+// translateOverallStatus 将主机总体状态翻译为中文
+func translateOverallStatus(status string) string {
+	switch status {
+	case "gray":
+		return "未知(可能是裸机状态)"
+	case "green":
+		return "正常"
+	case "yellow":
+		return "警告"
+	case "red":
+		return "异常"
+	default:
+		return status
+	}
+}
+
+// translatePowerState 将电源状态翻译为中文
+func translatePowerState(state string) string {
+	switch state {
+	case "poweredOn":
+		return "开机"
+	case "poweredOff":
+		return "关机"
+	case "standBy":
+		return "待机"
+	default:
+		return state
+	}
+}
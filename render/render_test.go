@@ -0,0 +1,74 @@
+package render_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/limou3434/work-esxi-controller/host"
+	"github.com/limou3434/work-esxi-controller/render"
+)
+
+func testInfo() *host.Info {
+	return &host.Info{
+		Name:          "esxi-01",
+		OverallStatus: types.ManagedEntityStatusGreen,
+		PowerState:    types.HostSystemPowerStatePoweredOn,
+		CpuMhz:        2400,
+		MemoryBytes:   8 * 1024 * 1024 * 1024,
+		Datastores: []host.DatastoreInfo{
+			{Name: "datastore1", CapacityBytes: 100 * 1024 * 1024 * 1024, FreeBytes: 40 * 1024 * 1024 * 1024},
+		},
+	}
+}
+
+func TestJSONRender(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (render.JSON{}).Render(&buf, testInfo()); err != nil {
+		t.Fatalf("渲染 JSON 失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"Name": "esxi-01"`) {
+		t.Fatalf("期望 JSON 输出包含主机名称, 实际: %s", buf.String())
+	}
+}
+
+func TestYAMLRender(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (render.YAML{}).Render(&buf, testInfo()); err != nil {
+		t.Fatalf("渲染 YAML 失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "name: esxi-01") {
+		t.Fatalf("期望 YAML 输出包含主机名称, 实际: %s", buf.String())
+	}
+}
+
+func TestPrometheusRender(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (render.Prometheus{}).Render(&buf, testInfo()); err != nil {
+		t.Fatalf("渲染 Prometheus 指标失败: %v", err)
+	}
+
+	out := buf.String()
+	for _, metric := range []string{
+		"esxi_host_cpu_mhz 2400",
+		"esxi_host_power_state 1",
+		`esxi_datastore_capacity_bytes{name="datastore1"}`,
+		`esxi_datastore_free_bytes{name="datastore1"}`,
+	} {
+		if !strings.Contains(out, metric) {
+			t.Fatalf("期望输出包含 %q, 实际: %s", metric, out)
+		}
+	}
+}
+
+func TestTableRender(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (render.Table{}).Render(&buf, testInfo()); err != nil {
+		t.Fatalf("渲染表格失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "esxi-01") {
+		t.Fatalf("期望表格输出包含主机名称, 实际: %s", buf.String())
+	}
+}
@@ -0,0 +1,43 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+
+	"github.com/limou3434/work-esxi-controller/host"
+)
+
+// Table 以 tablewriter 渲染的中文字段表格输出主机快照
+type Table struct{}
+
+// Render 实现 Renderer 接口
+func (Table) Render(w io.Writer, info *host.Info) error {
+	data := [][]string{
+		{"主机名称", info.Name},
+		{"总体状态", translateOverallStatus(string(info.OverallStatus))},
+		{"电源状态", translatePowerState(string(info.PowerState))},
+		{"中央处理", strconv.Itoa(int(info.CpuMhz)) + " MHz"},
+		{"内存大小", strconv.FormatInt(info.MemoryBytes/1024/1024/1024, 10) + " GB"},
+		{"存储数量", strconv.Itoa(len(info.Datastores)) + " 个磁盘"},
+	}
+
+	for _, ds := range info.Datastores {
+		capacityGB := ds.CapacityBytes / 1024 / 1024 / 1024
+		freeGB := ds.FreeBytes / 1024 / 1024 / 1024
+		uncommittedGB := capacityGB - freeGB
+		diskInfo := fmt.Sprintf("总容量: %d GB, 可用空间: %d GB, 已用空间: %d GB", capacityGB, freeGB, uncommittedGB)
+		data = append(data, []string{ds.Name, diskInfo})
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	table.Header([]string{"字段", "对应值"})
+	for _, row := range data {
+		table.Append(row)
+	}
+
+	return table.Render()
+}
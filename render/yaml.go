@@ -0,0 +1,26 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+	"github.com/limou3434/work-esxi-controller/host"
+)
+
+// YAML 以 YAML 格式输出主机快照
+type YAML struct{}
+
+// Render 实现 Renderer 接口
+func (YAML) Render(w io.Writer, info *host.Info) error {
+	encoder := yaml.NewEncoder(w)
+	defer encoder.Close()
+
+	if err := encoder.Encode(info); err != nil {
+		return fmt.Errorf("%w: YAML 编码失败: %v", errs.ErrInvalid, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// CloneSpec 描述克隆目标虚拟机时可选的放置信息, 留空字段使用源虚拟机所在位置的默认值
+type CloneSpec struct {
+	Folder       string // 目标文件夹路径, 为空则与源虚拟机相同
+	ResourcePool string // 目标资源池路径, 为空则使用默认资源池
+	Datastore    string // 目标数据存储路径, 为空则使用默认数据存储
+	PowerOn      bool   // 克隆完成后是否立即开机
+	Template     bool   // 是否将克隆结果标记为模板
+}
+
+// CloneVM 将 srcName 指定的虚拟机克隆为 dstName
+func (c *Controller) CloneVM(ctx context.Context, srcName string, dstName string, spec CloneSpec) (*object.VirtualMachine, error) {
+	src, err := c.lookup(ctx, srcName)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := c.resolveFolder(ctx, src, spec.Folder)
+	if err != nil {
+		return nil, err
+	}
+
+	relocateSpec, err := c.resolveRelocateSpec(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := src.Clone(ctx, folder, dstName, types.VirtualMachineCloneSpec{
+		Location: relocateSpec,
+		Template: spec.Template,
+		PowerOn:  spec.PowerOn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	result, err := task.WaitForResult(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return object.NewVirtualMachine(c.client.Client, result.Result.(types.ManagedObjectReference)), nil
+}
+
+// resolveFolder 解析目标文件夹, 未指定时回退到源虚拟机所在文件夹
+func (c *Controller) resolveFolder(ctx context.Context, src *object.VirtualMachine, path string) (*object.Folder, error) {
+	if path != "" {
+		return c.finder.Folder(ctx, path)
+	}
+
+	vmMO := mo.VirtualMachine{}
+	if err := c.client.RetrieveOne(ctx, src.Reference(), []string{"parent"}, &vmMO); err != nil {
+		return nil, fmt.Errorf("%w: 读取源虚拟机所在文件夹: %v", errs.ErrConnect, err)
+	}
+	if vmMO.Parent == nil {
+		return nil, fmt.Errorf("%w: 源虚拟机未关联文件夹", errs.ErrNotFound)
+	}
+
+	return object.NewFolder(c.client.Client, vmMO.Parent.Reference()), nil
+}
+
+// resolveRelocateSpec 根据 CloneSpec 中的可选字段解析出放置信息
+func (c *Controller) resolveRelocateSpec(ctx context.Context, spec CloneSpec) (types.VirtualMachineRelocateSpec, error) {
+	var relocateSpec types.VirtualMachineRelocateSpec
+
+	if spec.Datastore != "" {
+		ds, err := c.finder.Datastore(ctx, spec.Datastore)
+		if err != nil {
+			return relocateSpec, fmt.Errorf("%w: 数据存储 %s: %v", errs.ErrNotFound, spec.Datastore, err)
+		}
+		ref := ds.Reference()
+		relocateSpec.Datastore = &ref
+	}
+
+	if spec.ResourcePool != "" {
+		pool, err := c.finder.ResourcePool(ctx, spec.ResourcePool)
+		if err != nil {
+			return relocateSpec, fmt.Errorf("%w: 资源池 %s: %v", errs.ErrNotFound, spec.ResourcePool, err)
+		}
+		ref := pool.Reference()
+		relocateSpec.Pool = &ref
+	}
+
+	return relocateSpec, nil
+}
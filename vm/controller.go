@@ -0,0 +1,42 @@
+// Package vm 实现虚拟机生命周期管理, 包括开关机、克隆、OVF 导入等操作
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// Controller 基于 govmomi 客户端实现 VMController 接口
+type Controller struct {
+	client     *govmomi.Client
+	finder     *find.Finder
+	datacenter *object.Datacenter
+}
+
+// NewController 创建一个绑定到指定数据中心的虚拟机控制器实例
+func NewController(client *govmomi.Client, datacenter *object.Datacenter) *Controller {
+	finder := find.NewFinder(client.Client, true)
+	finder.SetDatacenter(datacenter)
+
+	return &Controller{
+		client:     client,
+		finder:     finder,
+		datacenter: datacenter,
+	}
+}
+
+// lookup 根据名字查找虚拟机实例, 未找到时返回 errs.ErrNotFound
+func (c *Controller) lookup(ctx context.Context, name string) (*object.VirtualMachine, error) {
+	vm, err := c.finder.VirtualMachine(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errs.ErrNotFound, name, err)
+	}
+
+	return vm, nil
+}
@@ -0,0 +1,168 @@
+package vm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/simulator"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+	"github.com/limou3434/work-esxi-controller/vm"
+)
+
+// newTestController 启动一个内置 vcsim 模拟的单机 ESXi 环境, 返回可直接使用的 Controller
+// 以及该环境预置的虚拟机名字列表 (ESX 模型默认预置两台)
+func newTestController(t *testing.T) (context.Context, *vm.Controller, []string, func()) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	model := simulator.ESX()
+	if err := model.Create(); err != nil {
+		t.Fatalf("创建模拟环境失败: %v", err)
+	}
+
+	server := model.Service.NewServer()
+
+	client, err := govmomi.NewClient(ctx, server.URL, true)
+	if err != nil {
+		model.Remove()
+		server.Close()
+		t.Fatalf("连接模拟环境失败: %v", err)
+	}
+
+	finder := find.NewFinder(client.Client, true)
+	datacenter, err := finder.DefaultDatacenter(ctx)
+	if err != nil {
+		t.Fatalf("查找默认数据中心失败: %v", err)
+	}
+	finder.SetDatacenter(datacenter)
+
+	vms, err := finder.VirtualMachineList(ctx, "*")
+	if err != nil {
+		t.Fatalf("列出预置虚拟机失败: %v", err)
+	}
+	names := make([]string, len(vms))
+	for i, v := range vms {
+		names[i] = v.Name()
+	}
+
+	cleanup := func() {
+		_ = client.Logout(ctx)
+		server.Close()
+		model.Remove()
+	}
+
+	return ctx, vm.NewController(client, datacenter), names, cleanup
+}
+
+func TestPowerOnOffVM(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	if err := controller.PowerOff(ctx, names[0]); err != nil {
+		t.Fatalf("关机失败: %v", err)
+	}
+
+	if err := controller.PowerOn(ctx, names[0]); err != nil {
+		t.Fatalf("开机失败: %v", err)
+	}
+}
+
+func TestPowerOnVMNotFound(t *testing.T) {
+	ctx, controller, _, cleanup := newTestController(t)
+	defer cleanup()
+
+	err := controller.PowerOn(ctx, "不存在的虚拟机")
+	if err == nil {
+		t.Fatal("期望返回 ErrVMNotFound, 实际为 nil")
+	}
+}
+
+func TestCloneVM(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	dstName := names[0] + "-clone"
+	clone, err := controller.CloneVM(ctx, names[0], dstName, vm.CloneSpec{})
+	if err != nil {
+		t.Fatalf("克隆失败: %v", err)
+	}
+	if clone.Reference().Type != "VirtualMachine" {
+		t.Fatalf("克隆结果类型不正确: %v", clone.Reference().Type)
+	}
+
+	if err := controller.DeleteVM(ctx, dstName); err != nil {
+		t.Fatalf("删除克隆失败: %v", err)
+	}
+}
+
+func TestResetAndSuspendVM(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	if err := controller.Reset(ctx, names[1]); err != nil {
+		t.Fatalf("重启失败: %v", err)
+	}
+
+	if err := controller.Suspend(ctx, names[1]); err != nil {
+		t.Fatalf("挂起失败: %v", err)
+	}
+}
+
+func TestCreateVMFromOVF(t *testing.T) {
+	ctx, controller, _, cleanup := newTestController(t)
+	defer cleanup()
+
+	const importedName = "ttylinux-import"
+
+	created, err := controller.CreateVMFromOVF(ctx, "testdata/ttylinux-pc_i486-16.1.ovf", importedName, "/ha-datacenter/vm", "LocalDS_0", "")
+	if err != nil {
+		t.Fatalf("导入 OVF 失败: %v", err)
+	}
+	if created.Reference().Type != "VirtualMachine" {
+		t.Fatalf("导入结果类型不正确: %v", created.Reference().Type)
+	}
+
+	if err := controller.DeleteVM(ctx, importedName); err != nil {
+		t.Fatalf("删除导入的虚拟机失败: %v", err)
+	}
+}
+
+func TestRebootGuestWithoutTools(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	// vcsim 预置的虚拟机未安装 VMware Tools, 期望返回 errs.ErrTaskFailed
+	err := controller.RebootGuest(ctx, names[0])
+	if !errors.Is(err, errs.ErrTaskFailed) {
+		t.Fatalf("期望返回 errs.ErrTaskFailed, 实际: %v", err)
+	}
+}
+
+func TestShutdownGuestVM(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	if err := controller.ShutdownGuest(ctx, names[0]); err != nil {
+		t.Fatalf("关闭客户机操作系统失败: %v", err)
+	}
+}
+
+func TestWaitForIPTimesOut(t *testing.T) {
+	ctx, controller, names, cleanup := newTestController(t)
+	defer cleanup()
+
+	// 预置虚拟机未上报 IP, 在短超时下应返回 errs.ErrTaskFailed 而非永久阻塞
+	timeoutCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	_, err := controller.WaitForIP(timeoutCtx, names[0])
+	if !errors.Is(err, errs.ErrTaskFailed) {
+		t.Fatalf("期望返回 errs.ErrTaskFailed, 实际: %v", err)
+	}
+}
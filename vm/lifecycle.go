@@ -0,0 +1,137 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vmware/govmomi/object"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// waitTask 提交任务实例并等待其完成, 失败时包装为 errs.ErrTaskFailed
+func waitTask(ctx context.Context, task *object.Task) error {
+	if err := task.Wait(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return nil
+}
+
+// PowerOn 开启指定虚拟机电源
+func (c *Controller) PowerOn(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.PowerOn(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return waitTask(ctx, task)
+}
+
+// PowerOff 关闭指定虚拟机电源
+func (c *Controller) PowerOff(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.PowerOff(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return waitTask(ctx, task)
+}
+
+// Reset 硬重启指定虚拟机
+func (c *Controller) Reset(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.Reset(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return waitTask(ctx, task)
+}
+
+// Suspend 挂起指定虚拟机
+func (c *Controller) Suspend(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.Suspend(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return waitTask(ctx, task)
+}
+
+// RebootGuest 通过 VMware Tools 重启客户机操作系统
+func (c *Controller) RebootGuest(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := vm.RebootGuest(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return nil
+}
+
+// ShutdownGuest 通过 VMware Tools 关闭客户机操作系统
+func (c *Controller) ShutdownGuest(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err := vm.ShutdownGuest(ctx); err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return nil
+}
+
+// DeleteVM 关闭并彻底删除指定虚拟机
+func (c *Controller) DeleteVM(ctx context.Context, name string) error {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	task, err := vm.Destroy(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return waitTask(ctx, task)
+}
+
+// WaitForIP 等待虚拟机通过 VMware Tools 上报 IP 地址
+func (c *Controller) WaitForIP(ctx context.Context, name string) (string, error) {
+	vm, err := c.lookup(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	ip, err := vm.WaitForIP(ctx)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errs.ErrTaskFailed, err)
+	}
+
+	return ip, nil
+}
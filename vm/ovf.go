@@ -0,0 +1,60 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/ovf/importer"
+
+	"github.com/limou3434/work-esxi-controller/errs"
+)
+
+// CreateVMFromOVF 解析本地 OVF 描述文件, 上传磁盘并在指定文件夹/数据存储/网络下注册新虚拟机
+func (c *Controller) CreateVMFromOVF(ctx context.Context, ovfPath string, name string, folder string, datastore string, network string) (*object.VirtualMachine, error) {
+	datastoreObj, err := c.finder.Datastore(ctx, datastore)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 数据存储 %s: %v", errs.ErrNotFound, datastore, err)
+	}
+
+	resourcePool, err := c.finder.DefaultResourcePool(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrNotFound, err)
+	}
+
+	folderObj, err := c.finder.Folder(ctx, folder)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 文件夹 %s: %v", errs.ErrNotFound, folder, err)
+	}
+
+	imp := importer.Importer{
+		Name:         name,
+		Client:       c.client.Client,
+		Finder:       c.finder,
+		Datacenter:   c.datacenter,
+		Datastore:    datastoreObj,
+		ResourcePool: resourcePool,
+		Folder:       folderObj,
+		Archive:      &importer.FileArchive{Path: ovfPath},
+		// Import 内部的上传进度日志器不能容忍 nil LogFunc, 因此必须显式提供
+		Log: func(msg string) (int, error) {
+			log.Print(msg)
+			return len(msg), nil
+		},
+	}
+
+	opts := importer.Options{
+		Name: &name,
+	}
+	if network != "" {
+		opts.NetworkMapping = []importer.Network{{Name: network, Network: network}}
+	}
+
+	ref, err := imp.Import(ctx, ovfPath, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errs.ErrInvalid, err)
+	}
+
+	return object.NewVirtualMachine(c.client.Client, *ref), nil
+}